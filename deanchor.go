@@ -0,0 +1,209 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	ErrYamlUnresolvedAlias = errors.New("yaml: unresolved alias")
+	ErrYamlAnchorCycle     = errors.New("yaml: anchor cycle")
+	ErrYamlInvalidMergeKey = errors.New("yaml: invalid merge key value")
+)
+
+// DeAnchorYAMLNode walks n and expands it in place so that it is free of
+// anchors, aliases and YAML merge keys (http://yaml.org/type/merge.html):
+//
+//   - every AliasNode is replaced by a deep clone of the node its anchor
+//     points to (comments on the alias usage itself are preserved on the
+//     clone);
+//   - every mapping pair whose key is a scalar "<<" tagged "!!merge" is
+//     expanded: the pairs of the merged mapping(s) are inserted into the
+//     parent mapping, without overwriting keys the parent already defines
+//     explicitly, and the "<<" pair itself is removed.
+//
+// Self-referential anchors are reported as ErrYamlAnchorCycle instead of
+// recursing forever.
+//
+// Once a tree has been passed through DeAnchorYAMLNode, MergeYAMLNodes no
+// longer needs to reason about AliasNode at all, since there is nothing left
+// to remap.
+func DeAnchorYAMLNode(n *yaml.Node) error {
+	anchors := map[string]*yaml.Node{}
+	collectAnchors(n, anchors)
+
+	_, err := deAnchorNode(n, anchors, map[*yaml.Node]bool{})
+	return err
+}
+
+func collectAnchors(n *yaml.Node, anchors map[string]*yaml.Node) {
+	if n == nil {
+		return
+	}
+
+	if n.Anchor != "" {
+		anchors[n.Anchor] = n
+	}
+
+	for _, c := range n.Content {
+		collectAnchors(c, anchors)
+	}
+}
+
+// deAnchorNode resolves n, returning the node that should take n's place in
+// its parent (n itself, unless n is an AliasNode, in which case it is a
+// resolved clone of the anchor's content).
+func deAnchorNode(n *yaml.Node, anchors map[string]*yaml.Node, visiting map[*yaml.Node]bool) (*yaml.Node, error) {
+	if n == nil {
+		return nil, nil
+	}
+
+	if n.Kind == yaml.AliasNode {
+		target, ok := anchors[n.Value]
+		if !ok {
+			return nil, fmt.Errorf("%w: *%s", ErrYamlUnresolvedAlias, n.Value)
+		}
+		if visiting[target] {
+			return nil, fmt.Errorf("%w: &%s", ErrYamlAnchorCycle, target.Anchor)
+		}
+
+		visiting[target] = true
+		clone := cloneYAMLNodeTree(target)
+		resolved, err := deAnchorNode(clone, anchors, visiting)
+		delete(visiting, target)
+		if err != nil {
+			return nil, err
+		}
+
+		if n.HeadComment != "" {
+			resolved.HeadComment = n.HeadComment
+		}
+		if n.LineComment != "" {
+			resolved.LineComment = n.LineComment
+		}
+		if n.FootComment != "" {
+			resolved.FootComment = n.FootComment
+		}
+
+		return resolved, nil
+	}
+
+	for i, c := range n.Content {
+		resolved, err := deAnchorNode(c, anchors, visiting)
+		if err != nil {
+			return nil, err
+		}
+		n.Content[i] = resolved
+	}
+
+	if n.Kind == yaml.MappingNode {
+		if err := expandMergeKeys(n); err != nil {
+			return nil, err
+		}
+	}
+
+	return n, nil
+}
+
+// cloneYAMLNodeTree deep copies n and all of its descendants into freshly
+// allocated nodes. The clone is not itself anchored, since an anchor name can
+// only be defined once in a document.
+func cloneYAMLNodeTree(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+
+	clone := *n
+	clone.Anchor = ""
+
+	if n.Content != nil {
+		clone.Content = make([]*yaml.Node, len(n.Content))
+		for i, c := range n.Content {
+			clone.Content[i] = cloneYAMLNodeTree(c)
+		}
+	}
+
+	return &clone
+}
+
+// expandMergeKeys finds "<<" (!!merge) pairs in mapping node n, inserts the
+// pairs of the merged mapping(s) that are not already explicitly present in
+// n, and removes the "<<" pairs. When multiple mappings are merged (via a
+// sequence value), earlier mappings take precedence over later ones; in all
+// cases explicit keys in n take precedence over merged ones.
+func expandMergeKeys(n *yaml.Node) error {
+	mergeIdx := map[int]bool{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		if key.Kind == yaml.ScalarNode && key.Value == "<<" && key.ShortTag() == "!!merge" {
+			mergeIdx[i] = true
+		}
+	}
+	if len(mergeIdx) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if mergeIdx[i] {
+			continue
+		}
+		seen[n.Content[i].Value] = true
+	}
+
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if !mergeIdx[i] {
+			continue
+		}
+
+		sources, err := mergeKeySources(n.Content[i+1])
+		if err != nil {
+			return err
+		}
+
+		for _, src := range sources {
+			for j := 0; j+1 < len(src.Content); j += 2 {
+				k, v := src.Content[j], src.Content[j+1]
+				if seen[k.Value] {
+					continue
+				}
+				seen[k.Value] = true
+				merged = append(merged, k, v)
+			}
+		}
+	}
+
+	rest := n.Content[:0]
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if mergeIdx[i] {
+			continue
+		}
+		rest = append(rest, n.Content[i], n.Content[i+1])
+	}
+	n.Content = append(rest, merged...)
+
+	return nil
+}
+
+// mergeKeySources returns the mappings a "<<" value refers to, in merge
+// order: a single mapping, or each mapping of a sequence.
+func mergeKeySources(val *yaml.Node) ([]*yaml.Node, error) {
+	switch val.Kind {
+	case yaml.MappingNode:
+		return []*yaml.Node{val}, nil
+	case yaml.SequenceNode:
+		sources := make([]*yaml.Node, 0, len(val.Content))
+		for _, item := range val.Content {
+			if item.Kind != yaml.MappingNode {
+				return nil, ErrYamlInvalidMergeKey
+			}
+			sources = append(sources, item)
+		}
+		return sources, nil
+	default:
+		return nil, ErrYamlInvalidMergeKey
+	}
+}
@@ -0,0 +1,222 @@
+package yaml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestDeAnchorYAMLNode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "no anchors - unchanged",
+			input: `
+foo: bar
+baz: 1
+`,
+			expected: `
+foo: bar
+baz: 1
+`,
+		},
+		{
+			name: "simple alias expanded to a deep copy",
+			input: `
+base: &BASE
+  x: 1
+  y: 2
+a: *BASE
+b: *BASE
+`,
+			expected: `
+base: &BASE
+  x: 1
+  y: 2
+a:
+  x: 1
+  y: 2
+b:
+  x: 1
+  y: 2
+`,
+		},
+		{
+			name: "merge one map - explicit keys keep their position, merged keys append",
+			input: `
+anchors:
+  - &CENTER { x: 1, y: 2 }
+mergeOne:
+  << : *CENTER
+  r: 10
+`,
+			expected: `
+anchors:
+  - &CENTER {x: 1, y: 2}
+mergeOne:
+  r: 10
+  x: 1
+  y: 2
+`,
+		},
+		{
+			name: "merge multiple maps - earlier source wins over later",
+			input: `
+anchors:
+  - &CENTER { x: 1, y: 2 }
+  - &BIG { r: 10, x: 99 }
+mergeMultiple:
+  << : [ *CENTER, *BIG ]
+  label: center/big
+`,
+			expected: `
+anchors:
+  - &CENTER {x: 1, y: 2}
+  - &BIG {r: 10, x: 99}
+mergeMultiple:
+  label: center/big
+  x: 1
+  y: 2
+  r: 10
+`,
+		},
+		{
+			name: "explicit keys always win over merged keys",
+			input: `
+anchors:
+  - &BIG { r: 10, x: 99 }
+override:
+  << : *BIG
+  x: 1
+`,
+			expected: `
+anchors:
+  - &BIG {r: 10, x: 99}
+override:
+  x: 1
+  r: 10
+`,
+		},
+		{
+			name: "inline map merge",
+			input: `
+inlineMap:
+  << : {x: 1, y: 2}
+  label: center/big
+`,
+			expected: `
+inlineMap:
+  label: center/big
+  x: 1
+  y: 2
+`,
+		},
+		{
+			name: "aliasing the same anchor twice does not alias the copies to each other",
+			input: `
+base: &BASE
+  x: 1
+a: *BASE
+b: *BASE
+`,
+			expected: `
+base: &BASE
+  x: 1
+a:
+  x: 1
+b:
+  x: 1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n, expected yaml.Node
+
+			NoError(t, yaml.Unmarshal([]byte(tt.input), &n))
+			NoError(t, yaml.Unmarshal([]byte(tt.expected), &expected))
+
+			NoError(t, DeAnchorYAMLNode(&n))
+
+			nBytes, err := yaml.Marshal(&n)
+			NoError(t, err)
+
+			expectedBytes, err := yaml.Marshal(&expected)
+			NoError(t, err)
+
+			if string(expectedBytes) != string(nBytes) {
+				Fail(t, errors.New("unexpected result"), "%s\n!=\n\n%s", string(expectedBytes), string(nBytes))
+			}
+		})
+	}
+}
+
+func TestDeAnchorYAMLNodeMutatedCopiesAreIndependent(t *testing.T) {
+	var n yaml.Node
+	NoError(t, yaml.Unmarshal([]byte(`
+base: &BASE
+  x: 1
+a: *BASE
+b: *BASE
+`), &n))
+
+	NoError(t, DeAnchorYAMLNode(&n))
+
+	doc := n.Content[0]
+	a := doc.Content[3]
+	b := doc.Content[5]
+
+	if a == b {
+		t.Fatalf("expected independent clones, got the same node")
+	}
+
+	a.Content[1].Value = "100"
+
+	bBytes, err := yaml.Marshal(b)
+	NoError(t, err)
+	if strings.Contains(string(bBytes), "100") {
+		t.Fatalf("mutating one alias's clone leaked into another: %s", string(bBytes))
+	}
+}
+
+func TestDeAnchorYAMLNodeErrors(t *testing.T) {
+	t.Run("unresolved alias", func(t *testing.T) {
+		n := yaml.Node{
+			Kind: yaml.DocumentNode,
+			Content: []*yaml.Node{
+				{Kind: yaml.AliasNode, Value: "MISSING"},
+			},
+		}
+
+		err := DeAnchorYAMLNode(&n)
+		ErrorContains(t, err, ErrYamlUnresolvedAlias.Error())
+	})
+
+	t.Run("anchor cycle", func(t *testing.T) {
+		var n yaml.Node
+		NoError(t, yaml.Unmarshal([]byte(`
+a: &A
+  self: *A
+`), &n))
+
+		err := DeAnchorYAMLNode(&n)
+		ErrorContains(t, err, ErrYamlAnchorCycle.Error())
+	})
+
+	t.Run("invalid merge key value", func(t *testing.T) {
+		var n yaml.Node
+		NoError(t, yaml.Unmarshal([]byte(`
+foo:
+  << : 1
+`), &n))
+
+		err := DeAnchorYAMLNode(&n)
+		ErrorContains(t, err, ErrYamlInvalidMergeKey.Error())
+	})
+}
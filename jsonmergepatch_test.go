@@ -0,0 +1,120 @@
+package yaml
+
+import (
+	"errors"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestApplyJSONMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst      string
+		patch    string
+		expected string
+	}{
+		{
+			name: "null deletes the key",
+			dst: `
+a: 1
+b: 2
+`,
+			patch: `
+b:
+`,
+			expected: `
+a: 1
+`,
+		},
+		{
+			name: "null for a key that is not present is a no-op",
+			dst: `
+a: 1
+`,
+			patch: `
+b:
+`,
+			expected: `
+a: 1
+`,
+		},
+		{
+			name: "non-null values still overwrite",
+			dst: `
+a: 1
+b: 2
+`,
+			patch: `
+b: 3
+`,
+			expected: `
+a: 1
+b: 3
+`,
+		},
+		{
+			name: "nested null deletes a nested key",
+			dst: `
+nested:
+  a: 1
+  b: 2
+`,
+			patch: `
+nested:
+  b:
+`,
+			expected: `
+nested:
+  a: 1
+`,
+		},
+		{
+			name: "sequences are replaced wholesale",
+			dst: `
+items:
+  - a
+  - b
+`,
+			patch: `
+items:
+  - c
+`,
+			expected: `
+items:
+  - c
+`,
+		},
+		{
+			name: "top-level null patch empties dst",
+			dst: `
+a: 1
+`,
+			patch:    `null`,
+			expected: ``,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst, patch, expected yaml.Node
+
+			NoError(t, yaml.Unmarshal([]byte(tt.dst), &dst))
+			NoError(t, yaml.Unmarshal([]byte(tt.patch), &patch))
+			NoError(t, yaml.Unmarshal([]byte(tt.expected), &expected))
+
+			err := ApplyJSONMergePatch(&dst, &patch)
+			NoError(t, err)
+
+			dstBytes, err := yaml.Marshal(&dst)
+			NoError(t, err)
+
+			expectedBytes, err := yaml.Marshal(&expected)
+			NoError(t, err)
+
+			if string(expectedBytes) != string(dstBytes) {
+				Fail(t, errors.New("unexpected result"), "%s\n!=\n\n%s", string(expectedBytes), string(dstBytes))
+			}
+		})
+	}
+}
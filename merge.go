@@ -18,8 +18,60 @@ var (
 // MergeYAMLNodes merges src into dst consuming src by:
 //
 // - merging mapping nodes
-// - replacing seq nodes
-func MergeYAMLNodes(dst, src *yaml.Node) error {
+// - merging or replacing seq nodes, depending on MergeOptions.SequenceStrategy
+//
+// Pass opts to opt into additional behavior, e.g. WithDeAnchor to expand
+// aliases and merge keys in dst and src before merging, or
+// WithSequenceStrategy to change how sequence nodes are combined.
+func MergeYAMLNodes(dst, src *yaml.Node, opts ...MergeOption) error {
+	o := newMergeOptions(opts)
+	if o.DeAnchor {
+		if err := DeAnchorYAMLNode(dst); err != nil {
+			return err
+		}
+		if err := DeAnchorYAMLNode(src); err != nil {
+			return err
+		}
+	}
+
+	m := &merger{opts: o}
+	return m.merge(nil, dst, src)
+}
+
+// ApplyJSONMergePatch applies patch to dst per RFC 7396: an explicit null in
+// patch deletes the corresponding key from dst (see NullDelete), sequences
+// are replaced wholesale (SeqReplace), and a top-level null patch empties
+// dst entirely. Other cases follow MergeYAMLNodes: a patch value whose node
+// kind differs from dst's fails with ErrYamlInvalidNodeKinds instead of
+// replacing it, and nulls nested inside a value that is added to dst (or that
+// replaces a null in dst) are kept rather than stripped.
+//
+// NullDelete only takes effect during the merge itself; it is unrelated to
+// FilterYAMLNullNodes, which is a separate post-merge pass that strips null
+// nodes already present in a tree.
+func ApplyJSONMergePatch(dst, patch *yaml.Node) error {
+	return MergeYAMLNodes(dst, patch, WithNullSemantics(NullDelete), WithSequenceStrategy(SeqReplace))
+}
+
+// merger carries the MergeOptions for a single MergeYAMLNodes call through
+// its recursive descent. The path of the nodes currently being merged
+// (mapping keys from the document root, with a "[]" segment for items of a
+// sequence merged by key) is passed to each method alongside them.
+type merger struct {
+	opts *MergeOptions
+}
+
+func (m *merger) merge(path []string, dst, src *yaml.Node) error {
+	if m.opts.Transformer != nil {
+		handled, err := m.opts.Transformer.Transform(path, dst, src)
+		if err != nil {
+			return err
+		}
+		if handled {
+			return nil
+		}
+	}
+
 	// ''
 	if dst.Kind == 0 {
 		*dst = *src
@@ -39,7 +91,7 @@ func MergeYAMLNodes(dst, src *yaml.Node) error {
 	}
 
 	if dst.Kind != src.Kind {
-		return ErrYamlInvalidNodeKinds
+		return m.conflict(path, dst, src, ErrYamlInvalidNodeKinds)
 	}
 
 	if src.HeadComment != "" {
@@ -67,7 +119,12 @@ func MergeYAMLNodes(dst, src *yaml.Node) error {
 			return ErrYamlManyDocs
 		}
 
-		return MergeYAMLNodes(dst.Content[0], src.Content[0])
+		if m.opts.NullSemantics == NullDelete && isNullScalar(src.Content[0]) {
+			*dst = yaml.Node{}
+			return nil
+		}
+
+		return m.merge(path, dst.Content[0], src.Content[0])
 	case yaml.MappingNode:
 		// We do not change node style.
 
@@ -75,7 +132,7 @@ func MergeYAMLNodes(dst, src *yaml.Node) error {
 		if dst.ShortTag() != src.ShortTag() {
 			if src.Tag != "" {
 				if dst.Tag != "" {
-					return ErrYamlUnmergable
+					return m.conflict(path, dst, src, ErrYamlUnmergable)
 				}
 				dst.Tag = src.Tag
 			}
@@ -85,21 +142,22 @@ func MergeYAMLNodes(dst, src *yaml.Node) error {
 		if dst.Anchor != src.Anchor {
 			if src.Anchor != "" {
 				if dst.Anchor != "" {
-					return ErrYamlUnmergable
+					return m.conflict(path, dst, src, ErrYamlUnmergable)
 				}
 				dst.Anchor = src.Anchor
 			}
 		}
 
-		return mergeMappingNodes(dst, src)
+		return m.mergeMappingNodes(path, dst, src)
 	case yaml.AliasNode:
-		// Alias contains a pointer to an ANCHOR node in the hierarchy.
-		// TODO: if it is a different anchor, we must remap the pointer to the node in dst.
-		//       otherwise it may point to the nodes that were left and shadowed in src.
+		// Alias contains a pointer to an ANCHOR node in the hierarchy. If it is
+		// a different anchor, its pointer would need remapping into dst, since
+		// otherwise it may point to nodes that were left and shadowed in src.
+		// Run the tree through DeAnchorYAMLNode (WithDeAnchor) beforehand to
+		// expand aliases into concrete values so there is nothing to remap.
 		*dst = *src
 	case yaml.SequenceNode:
-		// We do not concatenate sequence nodes.
-		*dst = *src
+		return m.mergeSequenceNodes(path, dst, src)
 	case yaml.ScalarNode:
 		*dst = *src
 	default:
@@ -109,20 +167,47 @@ func MergeYAMLNodes(dst, src *yaml.Node) error {
 	return nil
 }
 
+// conflict resolves a merge conflict that would otherwise fail with
+// fallback: if opts.ConflictFunc is set, it is called to produce a
+// replacement node for dst, letting callers coerce, prefer a side, or return
+// a domain-specific error; otherwise fallback is returned as-is.
+func (m *merger) conflict(path []string, dst, src *yaml.Node, fallback error) error {
+	if m.opts.ConflictFunc == nil {
+		return fallback
+	}
+
+	resolved, err := m.opts.ConflictFunc(path, dst, src)
+	if err != nil {
+		return err
+	}
+
+	*dst = *resolved
+	return nil
+}
+
 // mergeMappingNodes merges two mapping nodes.
-func mergeMappingNodes(dst, src *yaml.Node) error {
+func (m *merger) mergeMappingNodes(path []string, dst, src *yaml.Node) error {
 	dstMap := mapNodeToMap(dst)
+	toDelete := map[string]bool{}
 
 	for i := 0; i+1 < len(src.Content); i += 2 {
 		key := src.Content[i]
 		val := src.Content[i+1]
+		childPath := appendPath(path, key.Value)
+
+		if m.opts.NullSemantics == NullDelete && isNullScalar(val) {
+			// RFC 7396: a null in the patch deletes the key from the target,
+			// instead of overwriting it with null.
+			toDelete[key.Value] = true
+			continue
+		}
 
 		if dstPair, exists := dstMap[key.Value]; exists {
 			// key docs
-			if err := MergeYAMLNodes(dstPair.key, key); err != nil {
+			if err := m.merge(childPath, dstPair.key, key); err != nil {
 				return err
 			}
-			if err := MergeYAMLNodes(dstPair.val, val); err != nil {
+			if err := m.merge(childPath, dstPair.val, val); err != nil {
 				return err
 			}
 		} else {
@@ -130,9 +215,120 @@ func mergeMappingNodes(dst, src *yaml.Node) error {
 		}
 	}
 
+	if len(toDelete) > 0 {
+		rest := dst.Content[:0]
+		for i := 0; i+1 < len(dst.Content); i += 2 {
+			if toDelete[dst.Content[i].Value] {
+				continue
+			}
+			rest = append(rest, dst.Content[i], dst.Content[i+1])
+		}
+		dst.Content = rest
+	}
+
+	return nil
+}
+
+// isNullScalar reports whether n is an explicit or implicit YAML null scalar.
+func isNullScalar(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null"
+}
+
+// mergeSequenceNodes combines two sequence nodes per opts.SequenceStrategy.
+// The zero value, SeqReplace, keeps the original behavior of replacing dst
+// with src wholesale.
+func (m *merger) mergeSequenceNodes(path []string, dst, src *yaml.Node) error {
+	switch m.opts.SequenceStrategy {
+	case SeqAppend:
+		dst.Content = append(dst.Content, src.Content...)
+		return nil
+	case SeqPrepend:
+		dst.Content = append(append([]*yaml.Node{}, src.Content...), dst.Content...)
+		return nil
+	case SeqMergeByKey:
+		if m.opts.SeqMergeKeyFunc != nil {
+			if keyField, ok := m.opts.SeqMergeKeyFunc(path); ok {
+				return m.mergeSequenceByKey(path, keyField, dst, src)
+			}
+		}
+		fallthrough
+	default: // SeqReplace
+		*dst = *src
+		return nil
+	}
+}
+
+// mergeSequenceByKey pairs up mapping-node items of dst and src by the value
+// of their keyField field, recursively merging matched pairs in place, and
+// appends src items that have no matching dst item. Items that are not
+// mapping nodes, or that lack keyField, are left untouched in dst and cannot
+// be matched against from src.
+func (m *merger) mergeSequenceByKey(path []string, keyField string, dst, src *yaml.Node) error {
+	itemPath := appendPath(path, "[]")
+	matchedSrc := make([]bool, len(src.Content))
+
+	for _, d := range dst.Content {
+		dKey, ok := seqItemKey(d, keyField)
+		if !ok {
+			continue
+		}
+
+		for i, s := range src.Content {
+			if matchedSrc[i] {
+				continue
+			}
+			sKey, ok := seqItemKey(s, keyField)
+			if !ok || sKey != dKey {
+				continue
+			}
+
+			if err := m.merge(itemPath, d, s); err != nil {
+				return err
+			}
+			matchedSrc[i] = true
+			break
+		}
+	}
+
+	for i, s := range src.Content {
+		if !matchedSrc[i] {
+			dst.Content = append(dst.Content, s)
+		}
+	}
+
 	return nil
 }
 
+// seqItemKey returns the scalar value of item's keyField mapping entry, if
+// item is a mapping node that has one.
+func seqItemKey(item *yaml.Node, keyField string) (string, bool) {
+	if item.Kind != yaml.MappingNode {
+		return "", false
+	}
+
+	for i := 0; i+1 < len(item.Content); i += 2 {
+		if item.Content[i].Value != keyField {
+			continue
+		}
+		val := item.Content[i+1]
+		if val.Kind != yaml.ScalarNode {
+			return "", false
+		}
+		return val.Value, true
+	}
+
+	return "", false
+}
+
+// appendPath returns path with seg appended, without mutating or aliasing
+// path's backing array.
+func appendPath(path []string, seg string) []string {
+	p := make([]string, len(path)+1)
+	copy(p, path)
+	p[len(path)] = seg
+	return p
+}
+
 type yamlNodeKVPair struct {
 	key *yaml.Node
 	val *yaml.Node
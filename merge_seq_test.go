@@ -0,0 +1,133 @@
+package yaml
+
+import (
+	"errors"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestMergeYAMLNodesSequenceStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []MergeOption
+		dst      string
+		src      string
+		expected string
+	}{
+		{
+			name: "default - replace",
+			dst:  "- a\n- b\n",
+			src:  "- c\n",
+			expected: `
+- c
+`,
+		},
+		{
+			name: "SeqReplace - explicit",
+			opts: []MergeOption{WithSequenceStrategy(SeqReplace)},
+			dst:  "- a\n- b\n",
+			src:  "- c\n",
+			expected: `
+- c
+`,
+		},
+		{
+			name: "SeqAppend",
+			opts: []MergeOption{WithSequenceStrategy(SeqAppend)},
+			dst:  "- a\n- b\n",
+			src:  "- c\n",
+			expected: `
+- a
+- b
+- c
+`,
+		},
+		{
+			name: "SeqPrepend",
+			opts: []MergeOption{WithSequenceStrategy(SeqPrepend)},
+			dst:  "- a\n- b\n",
+			src:  "- c\n",
+			expected: `
+- c
+- a
+- b
+`,
+		},
+		{
+			name: "SeqAppend nested under a mapping",
+			opts: []MergeOption{WithSequenceStrategy(SeqAppend)},
+			dst:  "env:\n  - a\n  - b\n",
+			src:  "env:\n  - c\n",
+			expected: `
+env:
+  - a
+  - b
+  - c
+`,
+		},
+		{
+			name: "SeqMergeByKey - merges matching items and appends new ones",
+			opts: []MergeOption{WithSeqMergeKeyPaths(map[string]string{"/spec/containers": "name"})},
+			dst: `
+spec:
+  containers:
+    - name: app
+      image: app:1
+    - name: sidecar
+      image: sidecar:1
+`,
+			src: `
+spec:
+  containers:
+    - name: app
+      image: app:2
+    - name: logger
+      image: logger:1
+`,
+			expected: `
+spec:
+  containers:
+    - name: app
+      image: app:2
+    - name: sidecar
+      image: sidecar:1
+    - name: logger
+      image: logger:1
+`,
+		},
+		{
+			name: "SeqMergeByKey - path without a configured key field falls back to replace",
+			opts: []MergeOption{WithSeqMergeKeyPaths(map[string]string{"/spec/containers": "name"})},
+			dst:  "other:\n  - a\n  - b\n",
+			src:  "other:\n  - c\n",
+			expected: `
+other:
+  - c
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dst, src, expected yaml.Node
+
+			NoError(t, yaml.Unmarshal([]byte(tt.dst), &dst))
+			NoError(t, yaml.Unmarshal([]byte(tt.src), &src))
+			NoError(t, yaml.Unmarshal([]byte(tt.expected), &expected))
+
+			err := MergeYAMLNodes(&dst, &src, tt.opts...)
+			NoError(t, err)
+
+			dstBytes, err := yaml.Marshal(&dst)
+			NoError(t, err)
+
+			expectedBytes, err := yaml.Marshal(&expected)
+			NoError(t, err)
+
+			if string(expectedBytes) != string(dstBytes) {
+				Fail(t, errors.New("unexpected result"), "%s\n!=\n\n%s", string(expectedBytes), string(dstBytes))
+			}
+		})
+	}
+}
@@ -604,6 +604,47 @@ g:
 	}
 }
 
+func TestMergeYAMLNodesWithDeAnchor(t *testing.T) {
+	var dst, src, expected yaml.Node
+
+	NoError(t, yaml.Unmarshal([]byte(`
+base: &BASE
+  x: 1
+  y: 2
+a: *BASE
+`), &dst))
+	NoError(t, yaml.Unmarshal([]byte(`
+base: &BASE
+  x: 1
+  y: 2
+a:
+  << : *BASE
+  z: 3
+`), &src))
+	NoError(t, yaml.Unmarshal([]byte(`
+base: &BASE
+  x: 1
+  y: 2
+a:
+  x: 1
+  y: 2
+  z: 3
+`), &expected))
+
+	err := MergeYAMLNodes(&dst, &src, WithDeAnchor())
+	NoError(t, err)
+
+	dstBytes, err := yaml.Marshal(&dst)
+	NoError(t, err)
+
+	expectedBytes, err := yaml.Marshal(&expected)
+	NoError(t, err)
+
+	if string(expectedBytes) != string(dstBytes) {
+		Fail(t, errors.New("unexpected result"), "%s\n!=\n\n%s", string(expectedBytes), string(dstBytes))
+	}
+}
+
 func TestFilterYAMLNullNodes(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,201 @@
+package yaml
+
+import (
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// SequenceStrategy controls how MergeYAMLNodes combines two sequence nodes.
+type SequenceStrategy int
+
+const (
+	// SeqReplace replaces dst with src wholesale. This is the original,
+	// default behavior.
+	SeqReplace SequenceStrategy = iota
+	// SeqAppend appends src's items to dst's.
+	SeqAppend
+	// SeqPrepend prepends src's items before dst's.
+	SeqPrepend
+	// SeqMergeByKey pairs up mapping-node items of dst and src by a key
+	// field selected via MergeOptions.SeqMergeKeyFunc, recursively merging
+	// matched pairs and appending unmatched src items. Paths for which
+	// SeqMergeKeyFunc returns ok == false fall back to SeqReplace.
+	SeqMergeByKey
+)
+
+// NullSemantics controls what MergeYAMLNodes does when it encounters an
+// explicit or implicit null in src.
+type NullSemantics int
+
+const (
+	// NullOverwrite assigns dst the null value, like any other scalar. This
+	// is the original, default behavior.
+	NullOverwrite NullSemantics = iota
+	// NullDelete removes the corresponding key from dst instead, per RFC
+	// 7396 (JSON Merge Patch). See ApplyJSONMergePatch.
+	NullDelete
+)
+
+// MergeOptions controls the optional behavior of MergeYAMLNodes. The zero
+// value reproduces the original, option-free merge behavior.
+type MergeOptions struct {
+	// DeAnchor runs DeAnchorYAMLNode on dst and src before merging, expanding
+	// aliases and merge keys so the merge operates on concrete values only.
+	DeAnchor bool
+
+	// SequenceStrategy selects how sequence nodes are combined.
+	SequenceStrategy SequenceStrategy
+
+	// SeqMergeKeyFunc, when SequenceStrategy is SeqMergeByKey, selects the
+	// mapping key field that identifies items of the sequence at path (a
+	// mapping-key path from the document root, e.g. []string{"spec",
+	// "containers"}, in which items of a sequence merged by key appear as a
+	// "[]" segment). ok is false when no key field applies at that path.
+	SeqMergeKeyFunc func(path []string) (keyField string, ok bool)
+
+	// NullSemantics selects what a null in src means for mapping values.
+	NullSemantics NullSemantics
+
+	// StreamIdentityFunc identifies a document for MergeYAMLStreams. Two
+	// documents with the same identity are merged; otherwise they are
+	// treated as distinct resources. Defaults to apiVersion/kind/namespace/
+	// name when unset.
+	StreamIdentityFunc func(doc *yaml.Node) string
+
+	// Transformer, if set, is consulted before MergeYAMLNodes' default
+	// handling of every pair of nodes it merges. If it reports handled,
+	// the built-in logic for that pair is skipped entirely.
+	Transformer Transformer
+
+	// ConflictFunc, if set, is called instead of failing with
+	// ErrYamlUnmergable or ErrYamlInvalidNodeKinds, letting callers coerce
+	// the conflicting nodes, prefer a side, or return a domain-specific
+	// error. Its return value replaces dst.
+	ConflictFunc ConflictFunc
+}
+
+// MergeOption configures a MergeOptions instance.
+type MergeOption func(*MergeOptions)
+
+// Transformer intercepts MergeYAMLNodes at a given path, letting callers
+// implement custom precedence rules (e.g. semver merging, list-of-strings
+// unioning) instead of the built-in logic. handled reports whether dst was
+// fully handled by Transform; if false, MergeYAMLNodes proceeds as usual.
+type Transformer interface {
+	Transform(path []string, dst, src *yaml.Node) (handled bool, err error)
+}
+
+// TransformerFunc adapts a function to the Transformer interface.
+type TransformerFunc func(path []string, dst, src *yaml.Node) (handled bool, err error)
+
+// Transform calls fn.
+func (fn TransformerFunc) Transform(path []string, dst, src *yaml.Node) (bool, error) {
+	return fn(path, dst, src)
+}
+
+// ConflictFunc resolves a merge conflict at path between dst and src,
+// returning the node that should take dst's place.
+type ConflictFunc func(path []string, dst, src *yaml.Node) (*yaml.Node, error)
+
+// WithDeAnchor enables a DeAnchorYAMLNode pass over dst and src before they
+// are merged. See DeAnchorYAMLNode for details.
+func WithDeAnchor() MergeOption {
+	return func(o *MergeOptions) {
+		o.DeAnchor = true
+	}
+}
+
+// WithSequenceStrategy sets how sequence nodes are combined.
+func WithSequenceStrategy(s SequenceStrategy) MergeOption {
+	return func(o *MergeOptions) {
+		o.SequenceStrategy = s
+	}
+}
+
+// WithSeqMergeKeyFunc sets SequenceStrategy to SeqMergeByKey and uses fn to
+// select the key field per path.
+func WithSeqMergeKeyFunc(fn func(path []string) (keyField string, ok bool)) MergeOption {
+	return func(o *MergeOptions) {
+		o.SequenceStrategy = SeqMergeByKey
+		o.SeqMergeKeyFunc = fn
+	}
+}
+
+// WithNullSemantics selects what a null in src means for mapping values. See
+// NullSemantics.
+func WithNullSemantics(s NullSemantics) MergeOption {
+	return func(o *MergeOptions) {
+		o.NullSemantics = s
+	}
+}
+
+// WithSeqMergeKeyPaths sets SequenceStrategy to SeqMergeByKey, selecting the
+// key field by looking up a JSON-pointer-style path (e.g. "/spec/containers")
+// in keyFieldsByPath, mirroring Kubernetes' patchMergeKey annotations. Paths
+// not present in keyFieldsByPath fall back to SeqReplace.
+func WithSeqMergeKeyPaths(keyFieldsByPath map[string]string) MergeOption {
+	return WithSeqMergeKeyFunc(func(path []string) (string, bool) {
+		keyField, ok := keyFieldsByPath[pathPointer(path)]
+		return keyField, ok
+	})
+}
+
+// pathPointer renders path as a JSON-pointer-style string, e.g.
+// []string{"spec", "containers"} -> "/spec/containers".
+func pathPointer(path []string) string {
+	p := "/"
+	for i, seg := range path {
+		if i > 0 {
+			p += "/"
+		}
+		p += seg
+	}
+	return p
+}
+
+// WithStreamIdentityFunc sets the function MergeYAMLStreams uses to identify
+// documents. See MergeOptions.StreamIdentityFunc.
+func WithStreamIdentityFunc(fn func(doc *yaml.Node) string) MergeOption {
+	return func(o *MergeOptions) {
+		o.StreamIdentityFunc = fn
+	}
+}
+
+// WithStreamIdentityPaths sets the document identity MergeYAMLStreams uses
+// to the "/"-joined scalar values found at each path (a sequence of mapping
+// keys from the document's top-level mapping), so non-Kubernetes users can
+// key streams on any set of scalar fields.
+func WithStreamIdentityPaths(paths ...[]string) MergeOption {
+	return WithStreamIdentityFunc(func(doc *yaml.Node) string {
+		parts := make([]string, len(paths))
+		for i, p := range paths {
+			parts[i] = pathScalarValue(doc, p)
+		}
+		return strings.Join(parts, "/")
+	})
+}
+
+// WithTransformer sets the Transformer MergeYAMLNodes consults before its
+// default handling of every pair of nodes it merges.
+func WithTransformer(t Transformer) MergeOption {
+	return func(o *MergeOptions) {
+		o.Transformer = t
+	}
+}
+
+// WithConflictFunc sets the function called instead of failing with
+// ErrYamlUnmergable or ErrYamlInvalidNodeKinds. See ConflictFunc.
+func WithConflictFunc(fn ConflictFunc) MergeOption {
+	return func(o *MergeOptions) {
+		o.ConflictFunc = fn
+	}
+}
+
+func newMergeOptions(opts []MergeOption) *MergeOptions {
+	o := &MergeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
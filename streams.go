@@ -0,0 +1,141 @@
+package yaml
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// MergeYAMLStreams merges src into dst as two streams of resources, one
+// DocumentNode per resource (see SplitYAMLStream). Documents that share an
+// identity, per MergeOptions.StreamIdentityFunc (by default the tuple
+// apiVersion/kind/metadata.namespace/metadata.name, as in a stream of
+// Kubernetes manifests), are merged with MergeYAMLNodes; a src document whose
+// identity matches none of dst's is appended, and a later src document with
+// that same identity is merged into the appended one. dst's document order is
+// preserved, with new documents appended after it.
+func MergeYAMLStreams(dst, src []*yaml.Node, opts ...MergeOption) ([]*yaml.Node, error) {
+	o := newMergeOptions(opts)
+	identity := o.StreamIdentityFunc
+	if identity == nil {
+		identity = defaultStreamIdentity
+	}
+
+	result := make([]*yaml.Node, len(dst))
+	copy(result, dst)
+
+	index := make(map[string]int, len(result))
+	for i, doc := range result {
+		index[identity(doc)] = i
+	}
+
+	for _, doc := range src {
+		id := identity(doc)
+		if i, exists := index[id]; exists {
+			if err := MergeYAMLNodes(result[i], doc, opts...); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		result = append(result, doc)
+		index[id] = len(result) - 1
+	}
+
+	return result, nil
+}
+
+// SplitYAMLStream decodes r into one *yaml.Node DocumentNode per document,
+// since the standard decoder only returns documents one at a time.
+func SplitYAMLStream(r io.Reader) ([]*yaml.Node, error) {
+	dec := yaml.NewDecoder(r)
+
+	var docs []*yaml.Node
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		docs = append(docs, &doc)
+	}
+
+	return docs, nil
+}
+
+// defaultStreamIdentity keys a document by apiVersion/kind/namespace/name, as
+// is unique for a resource in a Kubernetes manifest or values stream.
+func defaultStreamIdentity(doc *yaml.Node) string {
+	m := docMapping(doc)
+
+	apiVersion := scalarField(m, "apiVersion")
+	kind := scalarField(m, "kind")
+
+	var namespace, name string
+	if meta := mappingField(m, "metadata"); meta != nil {
+		namespace = scalarField(meta, "namespace")
+		name = scalarField(meta, "name")
+	}
+
+	return strings.Join([]string{apiVersion, kind, namespace, name}, "/")
+}
+
+// docMapping returns doc's top-level mapping node, unwrapping a DocumentNode
+// if necessary, or nil if doc is not (or does not contain) a mapping.
+func docMapping(doc *yaml.Node) *yaml.Node {
+	if doc == nil {
+		return nil
+	}
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) != 1 {
+			return nil
+		}
+		doc = doc.Content[0]
+	}
+	if doc.Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc
+}
+
+// mappingField returns the value of key in mapping node m, or nil.
+func mappingField(m *yaml.Node, key string) *yaml.Node {
+	if m == nil {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalarField returns the scalar value of key in mapping node m, or "".
+func scalarField(m *yaml.Node, key string) string {
+	v := mappingField(m, key)
+	if v == nil || v.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return v.Value
+}
+
+// pathScalarValue walks path (a sequence of mapping keys) from doc's
+// top-level mapping and returns the scalar value found there, or "".
+func pathScalarValue(doc *yaml.Node, path []string) string {
+	n := docMapping(doc)
+	for _, seg := range path {
+		if n == nil {
+			return ""
+		}
+		n = mappingField(n, seg)
+	}
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return n.Value
+}
@@ -0,0 +1,148 @@
+package yaml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestSplitYAMLStream(t *testing.T) {
+	docs, err := SplitYAMLStream(strings.NewReader(`
+a: 1
+---
+b: 2
+---
+c: 3
+`))
+	NoError(t, err)
+
+	if len(docs) != 3 {
+		t.Fatalf("expected 3 documents, got %d", len(docs))
+	}
+
+	for i, want := range []string{"a: 1\n", "b: 2\n", "c: 3\n"} {
+		b, err := yaml.Marshal(docs[i])
+		NoError(t, err)
+		if string(b) != want {
+			t.Fatalf("document %d: got %q, want %q", i, string(b), want)
+		}
+	}
+}
+
+func TestSplitYAMLStreamEmpty(t *testing.T) {
+	docs, err := SplitYAMLStream(strings.NewReader(""))
+	NoError(t, err)
+	if len(docs) != 0 {
+		t.Fatalf("expected no documents, got %d", len(docs))
+	}
+}
+
+func TestMergeYAMLStreams(t *testing.T) {
+	dst, err := SplitYAMLStream(strings.NewReader(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+data:
+  a: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm2
+data:
+  a: "1"
+`))
+	NoError(t, err)
+
+	src, err := SplitYAMLStream(strings.NewReader(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+data:
+  a: "2"
+  b: "3"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm3
+data:
+  a: "1"
+`))
+	NoError(t, err)
+
+	result, err := MergeYAMLStreams(dst, src)
+	NoError(t, err)
+
+	if len(result) != 3 {
+		t.Fatalf("expected 3 documents, got %d", len(result))
+	}
+
+	names := make([]string, len(result))
+	for i, doc := range result {
+		names[i] = scalarField(mappingField(docMapping(doc), "metadata"), "name")
+	}
+	if want := []string{"cm1", "cm2", "cm3"}; !equalStrings(names, want) {
+		t.Fatalf("got names %v, want %v", names, want)
+	}
+
+	cm1Bytes, err := yaml.Marshal(result[0])
+	NoError(t, err)
+	expectedCM1 := `apiVersion: v1
+kind: ConfigMap
+metadata:
+    name: cm1
+data:
+    a: "2"
+    b: "3"
+`
+	if string(cm1Bytes) != expectedCM1 {
+		Fail(t, errors.New("unexpected result"), "%s\n!=\n\n%s", expectedCM1, string(cm1Bytes))
+	}
+}
+
+func TestMergeYAMLStreamsCustomIdentity(t *testing.T) {
+	dst, err := SplitYAMLStream(strings.NewReader(`
+id: foo
+value: 1
+`))
+	NoError(t, err)
+
+	src, err := SplitYAMLStream(strings.NewReader(`
+id: foo
+value: 2
+---
+id: bar
+value: 1
+`))
+	NoError(t, err)
+
+	result, err := MergeYAMLStreams(dst, src, WithStreamIdentityPaths([]string{"id"}))
+	NoError(t, err)
+
+	if len(result) != 2 {
+		t.Fatalf("expected 2 documents, got %d", len(result))
+	}
+
+	b, err := yaml.Marshal(result[0])
+	NoError(t, err)
+	if string(b) != "id: foo\nvalue: 2\n" {
+		t.Fatalf("got %q", string(b))
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
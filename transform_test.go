@@ -0,0 +1,122 @@
+package yaml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestMergeYAMLNodesTransformer(t *testing.T) {
+	// Union-of-strings transformer for sequences of scalars: instead of the
+	// default SeqReplace, concatenate and dedupe.
+	union := TransformerFunc(func(path []string, dst, src *yaml.Node) (bool, error) {
+		if dst.Kind != yaml.SequenceNode || src.Kind != yaml.SequenceNode {
+			return false, nil
+		}
+
+		seen := map[string]bool{}
+		var merged []*yaml.Node
+		for _, n := range append(append([]*yaml.Node{}, dst.Content...), src.Content...) {
+			if n.Kind != yaml.ScalarNode || seen[n.Value] {
+				continue
+			}
+			seen[n.Value] = true
+			merged = append(merged, n)
+		}
+		dst.Content = merged
+
+		return true, nil
+	})
+
+	var dst, src, expected yaml.Node
+	NoError(t, yaml.Unmarshal([]byte("tags:\n  - a\n  - b\n"), &dst))
+	NoError(t, yaml.Unmarshal([]byte("tags:\n  - b\n  - c\n"), &src))
+	NoError(t, yaml.Unmarshal([]byte("tags:\n  - a\n  - b\n  - c\n"), &expected))
+
+	NoError(t, MergeYAMLNodes(&dst, &src, WithTransformer(union)))
+
+	dstBytes, err := yaml.Marshal(&dst)
+	NoError(t, err)
+	expectedBytes, err := yaml.Marshal(&expected)
+	NoError(t, err)
+
+	if string(expectedBytes) != string(dstBytes) {
+		Fail(t, errors.New("unexpected result"), "%s\n!=\n\n%s", string(expectedBytes), string(dstBytes))
+	}
+}
+
+func TestMergeYAMLNodesTransformerError(t *testing.T) {
+	boom := errors.New("boom")
+	failing := TransformerFunc(func(path []string, dst, src *yaml.Node) (bool, error) {
+		return false, boom
+	})
+
+	var dst, src yaml.Node
+	NoError(t, yaml.Unmarshal([]byte("a: 1\n"), &dst))
+	NoError(t, yaml.Unmarshal([]byte("a: 2\n"), &src))
+
+	err := MergeYAMLNodes(&dst, &src, WithTransformer(failing))
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected boom, got %v", err)
+	}
+}
+
+func TestMergeYAMLNodesConflictFunc(t *testing.T) {
+	// Coerce a scalar dst into a one-element sequence instead of failing.
+	wrapInSeq := func(path []string, dst, src *yaml.Node) (*yaml.Node, error) {
+		if dst.Kind != yaml.ScalarNode || src.Kind != yaml.SequenceNode {
+			return nil, ErrYamlInvalidNodeKinds
+		}
+		scalar := &yaml.Node{Kind: dst.Kind, Tag: dst.Tag, Value: dst.Value, Style: dst.Style}
+		seq := &yaml.Node{Kind: yaml.SequenceNode, Content: append([]*yaml.Node{scalar}, src.Content...)}
+		return seq, nil
+	}
+
+	var dst, src, expected yaml.Node
+	NoError(t, yaml.Unmarshal([]byte("a\n"), &dst))
+	NoError(t, yaml.Unmarshal([]byte("- b\n- c\n"), &src))
+	NoError(t, yaml.Unmarshal([]byte("- a\n- b\n- c\n"), &expected))
+
+	NoError(t, MergeYAMLNodes(&dst, &src, WithConflictFunc(wrapInSeq)))
+
+	dstBytes, err := yaml.Marshal(&dst)
+	NoError(t, err)
+	expectedBytes, err := yaml.Marshal(&expected)
+	NoError(t, err)
+
+	if string(expectedBytes) != string(dstBytes) {
+		Fail(t, errors.New("unexpected result"), "%s\n!=\n\n%s", string(expectedBytes), string(dstBytes))
+	}
+}
+
+func TestMergeYAMLNodesConflictFuncDomainError(t *testing.T) {
+	pathErr := func(path []string, dst, src *yaml.Node) (*yaml.Node, error) {
+		return nil, errors.New("conflict at /" + strings.Join(path, "/"))
+	}
+
+	var dst, src yaml.Node
+	NoError(t, yaml.Unmarshal([]byte("nested:\n  a: 1\n"), &dst))
+	NoError(t, yaml.Unmarshal([]byte("nested:\n  a: [1]\n"), &src))
+
+	err := MergeYAMLNodes(&dst, &src, WithConflictFunc(pathErr))
+	ErrorContains(t, err, "conflict at /nested/a")
+}
+
+func TestMergeYAMLNodesConflictFuncNotCalledWithoutConflict(t *testing.T) {
+	called := false
+	fn := func(path []string, dst, src *yaml.Node) (*yaml.Node, error) {
+		called = true
+		return nil, errors.New("should not be called")
+	}
+
+	var dst, src yaml.Node
+	NoError(t, yaml.Unmarshal([]byte("a: 1\n"), &dst))
+	NoError(t, yaml.Unmarshal([]byte("a: 2\n"), &src))
+
+	NoError(t, MergeYAMLNodes(&dst, &src, WithConflictFunc(fn)))
+	if called {
+		t.Fatalf("ConflictFunc should not be called when there is no conflict")
+	}
+}